@@ -0,0 +1,48 @@
+package pkce
+
+import "testing"
+
+func Test_NewVerifier(t *testing.T) {
+	v, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("NewVerifier returned error: %v", err)
+	}
+	if len(v) < 43 || len(v) > 128 {
+		t.Fatalf("verifier length %d out of RFC 7636 bounds [43, 128]", len(v))
+	}
+
+	v2, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("NewVerifier returned error: %v", err)
+	}
+	if v == v2 {
+		t.Fatal("expected two verifiers to differ")
+	}
+}
+
+func Test_Challenge_S256(t *testing.T) {
+	got, err := Challenge("abc", MethodS256)
+	if err != nil {
+		t.Fatalf("Challenge returned error: %v", err)
+	}
+	want := "ungWv48Bz-pBQUDeXa4iI7ADYaOWF3qctBD_YfIAFa0"
+	if got != want {
+		t.Fatalf("Challenge(%q, %q) = %q, want %q", "abc", MethodS256, got, want)
+	}
+}
+
+func Test_Challenge_Plain(t *testing.T) {
+	got, err := Challenge("abc", MethodPlain)
+	if err != nil {
+		t.Fatalf("Challenge returned error: %v", err)
+	}
+	if got != "abc" {
+		t.Fatalf("Challenge(%q, %q) = %q, want %q", "abc", MethodPlain, got, "abc")
+	}
+}
+
+func Test_Challenge_UnsupportedMethod(t *testing.T) {
+	if _, err := Challenge("abc", "bogus"); err == nil {
+		t.Fatal("expected error for unsupported method")
+	}
+}
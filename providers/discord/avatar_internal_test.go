@@ -0,0 +1,33 @@
+package discord
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_avatarURLFor_CustomAvatar(t *testing.T) {
+	got := avatarURLFor("123", "abcd", "0")
+	want := "https://cdn.discordapp.com/avatars/123/abcd.png"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func Test_avatarURLFor_LegacyDiscriminator(t *testing.T) {
+	got := avatarURLFor("123", "", "4242")
+	want := fmt.Sprintf(defaultAvatarURL, 4242%5)
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func Test_avatarURLFor_NewStyleDiscriminator(t *testing.T) {
+	// Accounts migrated off discriminators report "0" and need their default
+	// avatar derived from the snowflake user ID instead.
+	const userID = "80351110224678912"
+	got := avatarURLFor(userID, "", "0")
+	want := fmt.Sprintf(defaultAvatarURL, (uint64(80351110224678912)>>22)%6)
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+// Package discord implements the OAuth2 protocol for authenticating users through Discord.
+// This package can be used as a reference implementation of an OAuth2 provider for Goth.
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authURL    string = "https://discord.com/api/oauth2/authorize"
+	tokenURL   string = "https://discord.com/api/oauth2/token"
+	profileURL string = "https://discord.com/api/users/@me"
+
+	avatarURL        string = "https://cdn.discordapp.com/avatars/%s/%s.png"
+	defaultAvatarURL string = "https://cdn.discordapp.com/embed/avatars/%d.png"
+)
+
+// Scopes Discord supports for the OAuth2 authorization code grant. Not
+// exhaustive, just the ones most consumers of this provider need.
+const (
+	ScopeIdentify   string = "identify"
+	ScopeEmail      string = "email"
+	ScopeGuilds     string = "guilds"
+	ScopeGuildsJoin string = "guilds.join"
+	ScopeBot        string = "bot"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Discord.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	config      *oauth2.Config
+
+	// Prompt controls Discord's `prompt` auth parameter ("none" or
+	// "consent"). Left empty, Discord's own default applies.
+	Prompt string
+}
+
+// New creates a new Discord provider and sets up important connection details.
+// You should always call `discord.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:   clientKey,
+		Secret:      secret,
+		CallbackURL: callbackURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return "discord"
+}
+
+// Debug is a no-op for the discord package.
+func (p *Provider) Debug(debug bool) {}
+
+// Client returns the HTTP client to use for requests to Discord, falling
+// back to goth's default client when none was configured.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BeginAuth asks Discord for an authentication end-point. When p.Prompt is
+// set it is forwarded as the `prompt` parameter ("none" skips the consent
+// screen on repeat authorizations, "consent" always shows it).
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	var opts []oauth2.AuthCodeOption
+	if p.Prompt != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("prompt", p.Prompt))
+	}
+
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, opts...),
+	}, nil
+}
+
+// FetchUser will go to Discord and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	s := session.(*Session)
+	user := goth.User{
+		AccessToken:  s.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: s.RefreshToken,
+		ExpiresAt:    s.ExpiresAt,
+	}
+
+	req, err := http.NewRequest("GET", profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+// UnmarshalSession wil unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+		Scopes: []string{ScopeIdentify},
+	}
+
+	if len(scopes) > 0 {
+		c.Scopes = []string{}
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	}
+	return c
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID            string `json:"id"`
+		Username      string `json:"username"`
+		Email         string `json:"email"`
+		Avatar        string `json:"avatar"`
+		Discriminator string `json:"discriminator"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.Name = u.Username
+	user.NickName = u.Username
+	user.Email = u.Email
+	user.AvatarURL = avatarURLFor(u.ID, u.Avatar, u.Discriminator)
+
+	return nil
+}
+
+// avatarURLFor builds the CDN URL for a user's avatar, falling back to one
+// of Discord's default avatars when the user has not set a custom one.
+// Accounts migrated to Discord's username system all report discriminator
+// "0" and need their default avatar index derived from the snowflake user
+// ID instead: (user_id >> 22) % 6. Legacy discriminators still use
+// discriminator % 5.
+func avatarURLFor(userID, avatar, discriminator string) string {
+	if avatar != "" {
+		return fmt.Sprintf(avatarURL, userID, avatar)
+	}
+
+	index := 0
+	if discriminator == "0" || discriminator == "" {
+		if id, err := strconv.ParseUint(userID, 10, 64); err == nil {
+			index = int((id >> 22) % 6)
+		}
+	} else if n, err := fmt.Sscanf(discriminator, "%d", &index); err == nil && n == 1 {
+		index %= 5
+	}
+	return fmt.Sprintf(defaultAvatarURL, index)
+}
+
+//RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+//RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(oauth2.NoContext, token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,63 @@
+package discord_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/discord"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("DISCORD_KEY"))
+	a.Equal(p.Secret, os.Getenv("DISCORD_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*discord.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "discord.com/api/oauth2/authorize")
+}
+
+func Test_BeginAuth_WithPrompt(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	p.Prompt = "none"
+	session, err := p.BeginAuth("test_state")
+	s := session.(*discord.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "prompt=none")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://discord.com/api/oauth2/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*discord.Session)
+	a.Equal(s.AuthURL, "https://discord.com/api/oauth2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func provider() *discord.Provider {
+	return discord.New(os.Getenv("DISCORD_KEY"), os.Getenv("DISCORD_SECRET"), "/foo", discord.ScopeIdentify, discord.ScopeEmail)
+}
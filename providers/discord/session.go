@@ -0,0 +1,73 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+// Session stores data during the auth process with Discord.
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+
+	// Scope holds the space-delimited list of scopes Discord actually
+	// granted, as returned alongside the access token. It may be a subset of
+	// what was requested, so callers that gate behavior on a scope (e.g.
+	// `guilds.join`) should check this rather than assuming the request.
+	Scope string
+}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Discord provider.
+func (s *Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Discord and return the access token to be stored for future use.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	if scope, ok := token.Extra("scope").(string); ok {
+		s.Scope = scope
+	}
+	return token.AccessToken, err
+}
+
+// HasScope reports whether scope was among the scopes Discord granted.
+func (s *Session) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(s.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Marshal the session into a string
+func (s *Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s *Session) String() string {
+	return s.Marshal()
+}
@@ -0,0 +1,40 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SessionGetAuthURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &Session{}
+
+	_, err := s.GetAuthURL()
+	a.Error(err)
+
+	s.AuthURL = "/foo"
+	url, err := s.GetAuthURL()
+	a.NoError(err)
+	a.Equal(url, "/foo")
+}
+
+func Test_SessionToJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &Session{}
+
+	data := s.Marshal()
+	a.Equal(data, `{"AuthURL":"","AccessToken":"","RefreshToken":"","ExpiresAt":"0001-01-01T00:00:00Z","Scope":""}`)
+}
+
+func Test_HasScope(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &Session{Scope: "identify email"}
+
+	a.True(s.HasScope("identify"))
+	a.True(s.HasScope("email"))
+	a.False(s.HasScope("guilds.join"))
+}
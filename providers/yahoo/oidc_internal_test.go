@@ -0,0 +1,127 @@
+package yahoo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/markbates/goth"
+)
+
+// fakeIDToken and fakeVerifier let fetchUserOIDC be exercised without a real
+// JWKS endpoint.
+type fakeIDToken struct {
+	nonce  string
+	claims interface{}
+}
+
+func (f fakeIDToken) GetNonce() string { return f.nonce }
+
+func (f fakeIDToken) Claims(v interface{}) error {
+	b, err := json.Marshal(f.claims)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+type fakeVerifier struct {
+	token fakeIDToken
+	err   error
+}
+
+func (f fakeVerifier) Verify(ctx context.Context, rawIDToken string) (verifiedIDToken, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.token, nil
+}
+
+func Test_NewOIDC_DoesNotDiscoverEagerly(t *testing.T) {
+	p := NewOIDC("key", "secret", "/foo")
+	if p.verifier != nil {
+		t.Fatal("NewOIDC should defer discovery to BeginAuth/FetchUser, but verifier is already set")
+	}
+	if p.config == nil || p.config.Endpoint.AuthURL != authURL {
+		t.Fatal("NewOIDC should configure the default (pre-discovery) endpoint immediately")
+	}
+}
+
+func Test_fetchUserOIDC_NonceMismatch(t *testing.T) {
+	p := &Provider{
+		oidc:     true,
+		verifier: fakeVerifier{token: fakeIDToken{nonce: "actual-nonce", claims: map[string]string{"sub": "123"}}},
+	}
+	s := &Session{IDToken: "some-id-token", Nonce: "expected-nonce"}
+
+	_, err := p.fetchUserOIDC(s, goth.User{})
+	if err == nil {
+		t.Fatal("expected an error for a mismatched nonce, got nil")
+	}
+}
+
+func Test_Authorize_MissingIDToken(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+		})
+	}))
+	defer tokenServer.Close()
+
+	p := &Provider{
+		ClientKey:   "key",
+		Secret:      "secret",
+		CallbackURL: "/foo",
+		HTTPClient:  tokenServer.Client(),
+		tokenURL:    tokenServer.URL,
+		oidc:        true,
+	}
+	p.config = newConfig(p, []string{"openid"})
+
+	s := &Session{}
+	if _, err := s.Authorize(p, url.Values{"code": {"abc"}}); err == nil {
+		t.Fatal("expected an error when the token response has no id_token, got nil")
+	}
+}
+
+func Test_fetchUserOIDC_UserinfoFallback(t *testing.T) {
+	userinfoServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer access-token" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer access-token")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"sub":   "123",
+			"email": "jdoe@example.com",
+			"name":  "Jane Doe",
+		})
+	}))
+	defer userinfoServer.Close()
+
+	p := &Provider{
+		oidc:        true,
+		userinfoURL: userinfoServer.URL,
+		HTTPClient:  userinfoServer.Client(),
+		verifier: fakeVerifier{token: fakeIDToken{
+			nonce:  "n",
+			claims: map[string]string{"sub": "123"}, // no email in the id_token
+		}},
+	}
+	s := &Session{IDToken: "some-id-token", Nonce: "n", AccessToken: "access-token"}
+
+	user, err := p.fetchUserOIDC(s, goth.User{AccessToken: s.AccessToken})
+	if err != nil {
+		t.Fatalf("fetchUserOIDC returned error: %v", err)
+	}
+	if user.Email != "jdoe@example.com" {
+		t.Errorf("Email = %q, want %q", user.Email, "jdoe@example.com")
+	}
+	if user.Name != "Jane Doe" {
+		t.Errorf("Name = %q, want %q", user.Name, "Jane Doe")
+	}
+}
@@ -0,0 +1,85 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// Session stores data during the auth process with Yahoo.
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+
+	// Nonce and IDToken are only populated when the Provider was created with
+	// NewOIDC. Nonce is the value sent in the authorization request and is
+	// checked against the id_token's `nonce` claim in FetchUser. Claims holds
+	// the raw id_token claims (sub, email_verified, ...) for callers that need
+	// fields beyond what goth.User exposes.
+	Nonce   string
+	IDToken string
+	Claims  json.RawMessage
+
+	// CodeVerifier is only populated when the Provider has PKCE enabled
+	// (SetPKCE/WithPKCE). It is generated in BeginAuth and sent back as
+	// code_verifier during the token exchange in Authorize, so it must
+	// survive UnmarshalSession across the redirect just like the rest of
+	// this struct.
+	CodeVerifier string
+}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Yahoo provider.
+func (s *Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Yahoo and return the access token to be stored for future use.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+
+	var opts []oauth2.AuthCodeOption
+	if s.CodeVerifier != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", s.CodeVerifier))
+	}
+
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+
+	if p.oidc {
+		rawIDToken, ok := token.Extra("id_token").(string)
+		if !ok || rawIDToken == "" {
+			return "", errors.New("yahoo: oidc token response missing id_token")
+		}
+		s.IDToken = rawIDToken
+	}
+
+	return token.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s *Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s *Session) String() string {
+	return s.Marshal()
+}
@@ -3,12 +3,19 @@
 package yahoo
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/internal/pkce"
 	"golang.org/x/oauth2"
 
 	"io"
 	"net/http"
+	"sync"
 
 	"strings"
 )
@@ -17,6 +24,11 @@ const (
 	authURL         string = "https://api.login.yahoo.com/oauth2/request_auth"
 	tokenURL        string = "https://api.login.yahoo.com/oauth2/get_token"
 	endpointProfile string = "https://social.yahooapis.com/v1/user/GUID/profile?format=json"
+
+	// issuerOIDC and endpointUserinfoOIDC are only used by providers created
+	// with NewOIDC.
+	issuerOIDC           string = "https://api.login.yahoo.com"
+	endpointUserinfoOIDC string = "https://api.login.yahoo.com/openid/v1/userinfo"
 )
 
 // Provider is the implementation of `goth.Provider` for accessing Yahoo.
@@ -24,22 +36,164 @@ type Provider struct {
 	ClientKey   string
 	Secret      string
 	CallbackURL string
+	HTTPClient  *http.Client
 	config      *oauth2.Config
+
+	authURL     string
+	tokenURL    string
+	profileURL  string
+	userinfoURL string
+
+	// oidc, oidcMu, oidcReady and verifier are set when the Provider was
+	// created with NewOIDC; they switch BeginAuth/FetchUser from the legacy
+	// profile API to the OpenID Connect flow. Discovery against issuerOIDC is
+	// deferred to the first call that needs it (see ensureOIDCDiscovery)
+	// rather than done in NewOIDC. oidcReady is only set once discovery has
+	// actually succeeded, so a transient failure (Yahoo briefly unreachable,
+	// a DNS hiccup) is retried on the next call instead of poisoning the
+	// Provider for good; oidcMu guards both against concurrent callers.
+	oidc      bool
+	oidcMu    sync.Mutex
+	oidcReady bool
+	verifier  idTokenVerifier
+
+	// pkceMethod is non-empty once PKCE has been enabled via SetPKCE or
+	// WithPKCE, and holds the code_challenge_method ("S256" or "plain") to
+	// use in BeginAuth.
+	pkceMethod string
+}
+
+// SetPKCE enables PKCE (RFC 7636) on the authorization code flow, using
+// method ("S256" or "plain") as the code_challenge_method. An empty method
+// defaults to "S256", which is what Yahoo and most providers expect.
+// It returns the Provider so it can be chained off a constructor.
+func (p *Provider) SetPKCE(method string) *Provider {
+	if method == "" {
+		method = pkce.MethodS256
+	}
+	p.pkceMethod = method
+	return p
+}
+
+// WithPKCE is a convenience for SetPKCE(pkce.MethodS256), for chaining off
+// New: `yahoo.New(key, secret, callback).WithPKCE()`.
+func (p *Provider) WithPKCE() *Provider {
+	return p.SetPKCE(pkce.MethodS256)
 }
 
 // New creates a new Yahoo provider and sets up important connection details.
 // You should always call `yahoo.New` to get a new provider.  Never try to
 // create one manually.
 func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, endpointProfile, scopes...)
+}
+
+// NewCustomisedURL is like New but for Yahoo deployments that use different
+// hosts than the default api.login.yahoo.com, such as Yahoo Japan or Yahoo
+// Small Business. Pass the region's authorize, token and profile endpoints
+// explicitly.
+func NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, profileURL string, scopes ...string) *Provider {
 	p := &Provider{
 		ClientKey:   clientKey,
 		Secret:      secret,
 		CallbackURL: callbackURL,
+		authURL:     authURL,
+		tokenURL:    tokenURL,
+		profileURL:  profileURL,
 	}
 	p.config = newConfig(p, scopes)
 	return p
 }
 
+// NewOIDC creates a new Yahoo provider that authenticates through Yahoo's
+// OpenID Connect flow instead of the legacy profile API used by New. It
+// requests the `openid`, `email` and `profile` scopes, verifies the id_token
+// returned during token exchange against Yahoo's JWKS
+// (https://api.login.yahoo.com/openid/v1/certs) and populates goth.User
+// (including Email, which the legacy flow always leaves blank) from the
+// id_token claims, falling back to the userinfo endpoint where needed.
+//
+// NewOIDC does not do any network I/O itself: discovering
+// Yahoo's OpenID Connect configuration (its token/JWKS endpoints) is
+// deferred to the first BeginAuth or FetchUser call, since Providers are
+// normally constructed once at application startup (e.g. via
+// goth.UseProviders) and a slow or unreachable discovery endpoint should
+// not be able to block that.
+func NewOIDC(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:   clientKey,
+		Secret:      secret,
+		CallbackURL: callbackURL,
+		authURL:     authURL,
+		tokenURL:    tokenURL,
+		profileURL:  endpointProfile,
+		userinfoURL: endpointUserinfoOIDC,
+		oidc:        true,
+	}
+
+	oidcScopes := append([]string{oidc.ScopeOpenID, "email", "profile"}, scopes...)
+	p.config = newConfig(p, oidcScopes)
+
+	return p
+}
+
+// ensureOIDCDiscovery performs Yahoo's OpenID Connect discovery, updating
+// p.config's endpoint and p.verifier. The result is only cached on success;
+// a failed attempt (a transient network blip, Yahoo briefly unreachable) is
+// retried on the next call rather than permanently disabling OIDC for this
+// Provider.
+func (p *Provider) ensureOIDCDiscovery() error {
+	p.oidcMu.Lock()
+	defer p.oidcMu.Unlock()
+
+	if p.oidcReady {
+		return nil
+	}
+
+	oidcProvider, err := oidc.NewProvider(goth.ContextForClient(p.Client()), issuerOIDC)
+	if err != nil {
+		return err
+	}
+	p.config.Endpoint = oidcProvider.Endpoint()
+	p.verifier = oidcIDTokenVerifier{oidcProvider.Verifier(&oidc.Config{ClientID: p.ClientKey})}
+	p.oidcReady = true
+	return nil
+}
+
+// idTokenVerifier and verifiedIDToken are the subset of *oidc.IDTokenVerifier
+// and *oidc.IDToken that fetchUserOIDC needs, pulled out as interfaces so
+// tests can exercise nonce/claims handling with a fake verifier instead of a
+// real JWKS endpoint.
+type idTokenVerifier interface {
+	Verify(ctx context.Context, rawIDToken string) (verifiedIDToken, error)
+}
+
+type verifiedIDToken interface {
+	GetNonce() string
+	Claims(v interface{}) error
+}
+
+// oidcIDTokenVerifier adapts *oidc.IDTokenVerifier to idTokenVerifier.
+type oidcIDTokenVerifier struct {
+	v *oidc.IDTokenVerifier
+}
+
+func (o oidcIDTokenVerifier) Verify(ctx context.Context, rawIDToken string) (verifiedIDToken, error) {
+	t, err := o.v.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	return oidcIDToken{t}, nil
+}
+
+// oidcIDToken adapts *oidc.IDToken to verifiedIDToken.
+type oidcIDToken struct {
+	t *oidc.IDToken
+}
+
+func (a oidcIDToken) GetNonce() string           { return a.t.Nonce }
+func (a oidcIDToken) Claims(v interface{}) error { return a.t.Claims(v) }
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return "yahoo"
@@ -48,11 +202,53 @@ func (p *Provider) Name() string {
 // Debug is a no-op for the yahoo package.
 func (p *Provider) Debug(debug bool) {}
 
-// BeginAuth asks Yahoo for an authentication end-point.
+// Client returns the HTTP client to use for requests to Yahoo, falling back
+// to goth's default client when none was configured.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BeginAuth asks Yahoo for an authentication end-point. When the provider was
+// created with NewOIDC a nonce is generated and attached to the auth URL so
+// FetchUser can later check it against the id_token's `nonce` claim. When
+// PKCE has been enabled (SetPKCE/WithPKCE) a code_verifier is generated and
+// stored on the session, and its derived code_challenge is attached to the
+// auth URL alongside code_challenge_method.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
-	}, nil
+	s := &Session{}
+	var opts []oauth2.AuthCodeOption
+
+	if p.oidc {
+		if err := p.ensureOIDCDiscovery(); err != nil {
+			return nil, err
+		}
+
+		nonce, err := randomString(32)
+		if err != nil {
+			return nil, err
+		}
+		s.Nonce = nonce
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+
+	if p.pkceMethod != "" {
+		verifier, err := pkce.NewVerifier()
+		if err != nil {
+			return nil, err
+		}
+		challenge, err := pkce.Challenge(verifier, p.pkceMethod)
+		if err != nil {
+			return nil, err
+		}
+		s.CodeVerifier = verifier
+		opts = append(opts,
+			oauth2.SetAuthURLParam("code_challenge", challenge),
+			oauth2.SetAuthURLParam("code_challenge_method", p.pkceMethod),
+		)
+	}
+
+	s.AuthURL = p.config.AuthCodeURL(state, opts...)
+	return s, nil
 }
 
 // FetchUser will go to Yahoo and access basic information about the user.
@@ -64,12 +260,20 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		RefreshToken: s.RefreshToken,
 		ExpiresAt:    s.ExpiresAt,
 	}
-	req, err := http.NewRequest("GET", endpointProfile, nil)
+
+	if p.oidc {
+		if err := p.ensureOIDCDiscovery(); err != nil {
+			return user, err
+		}
+		return p.fetchUserOIDC(s, user)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
 	if err != nil {
 		return user, err
 	}
 	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := p.Client().Do(req)
 	if err != nil {
 		if resp != nil {
 			resp.Body.Close()
@@ -82,6 +286,97 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// fetchUserOIDC verifies s.IDToken and populates user from its claims. Only
+// when the id_token carries no email does it call the OIDC userinfo endpoint,
+// which then supplies the email and any other fields still left empty.
+func (p *Provider) fetchUserOIDC(s *Session, user goth.User) (goth.User, error) {
+	if s.IDToken == "" {
+		return user, errors.New("yahoo: no id_token in session")
+	}
+
+	idToken, err := p.verifier.Verify(goth.ContextForClient(p.Client()), s.IDToken)
+	if err != nil {
+		return user, err
+	}
+
+	if s.Nonce != "" && idToken.GetNonce() != s.Nonce {
+		return user, errors.New("yahoo: id_token nonce does not match request")
+	}
+
+	var claims struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Nickname      string `json:"nickname"`
+		Picture       string `json:"picture"`
+		Locale        string `json:"locale"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return user, err
+	}
+	if err := idToken.Claims(&s.Claims); err != nil {
+		return user, err
+	}
+
+	user.UserID = claims.Subject
+	user.Email = claims.Email
+	user.Name = claims.Name
+	user.NickName = claims.Nickname
+	user.AvatarURL = claims.Picture
+	user.Location = claims.Locale
+
+	if user.Email == "" {
+		if err := p.fetchUserinfoOIDC(s, &user); err != nil {
+			return user, err
+		}
+	}
+
+	return user, nil
+}
+
+func (p *Provider) fetchUserinfoOIDC(s *Session, user *goth.User) error {
+	req, err := http.NewRequest("GET", p.userinfoURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	info := struct {
+		Subject  string `json:"sub"`
+		Email    string `json:"email"`
+		Name     string `json:"name"`
+		Nickname string `json:"nickname"`
+		Picture  string `json:"picture"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return err
+	}
+
+	if user.UserID == "" {
+		user.UserID = info.Subject
+	}
+	user.Email = info.Email
+	if user.Name == "" {
+		user.Name = info.Name
+	}
+	if user.NickName == "" {
+		user.NickName = info.Nickname
+	}
+	if user.AvatarURL == "" {
+		user.AvatarURL = info.Picture
+	}
+	return nil
+}
+
 // UnmarshalSession wil unmarshal a JSON string into a session.
 func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
 	s := &Session{}
@@ -95,8 +390,8 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 		ClientSecret: provider.Secret,
 		RedirectURL:  provider.CallbackURL,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  authURL,
-			TokenURL: tokenURL,
+			AuthURL:  provider.authURL,
+			TokenURL: provider.tokenURL,
 		},
 		Scopes: []string{},
 	}
@@ -133,6 +428,16 @@ func userFromReader(r io.Reader, user *goth.User) error {
 	return nil
 }
 
+// randomString returns a URL-safe, base64 encoded securely generated random
+// string of the requested byte length.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 //RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
 	return true
@@ -147,4 +452,4 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 		return nil, err
 	}
 	return newToken, err
-}
\ No newline at end of file
+}
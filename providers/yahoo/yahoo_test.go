@@ -0,0 +1,132 @@
+package yahoo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("YAHOO_KEY"))
+	a.Equal(p.Secret, os.Getenv("YAHOO_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*yahoo.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "api.login.yahoo.com/oauth2/request_auth")
+}
+
+func Test_NewCustomisedURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := yahoo.NewCustomisedURL(
+		os.Getenv("YAHOO_KEY"), os.Getenv("YAHOO_SECRET"), "/foo",
+		"https://auth.login.yahoo.co.jp/yconnect/v2/authorization",
+		"https://auth.login.yahoo.co.jp/yconnect/v2/token",
+		"https://userinfo.yahooapis.jp/yconnect/v2/attribute",
+	)
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*yahoo.Session)
+	a.Contains(s.AuthURL, "auth.login.yahoo.co.jp/yconnect/v2/authorization")
+}
+
+func Test_NewCustomisedURL_TokenExchangeAndProfileFetch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "customised-access-token",
+			"token_type":   "Bearer",
+		})
+	}))
+	defer tokenServer.Close()
+
+	profileServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer customised-access-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"profile": map[string]string{
+				"nickname": "jdoe",
+				"guid":     "abc123",
+			},
+		})
+	}))
+	defer profileServer.Close()
+
+	p := yahoo.NewCustomisedURL(
+		os.Getenv("YAHOO_KEY"), os.Getenv("YAHOO_SECRET"), "/foo",
+		"https://auth.login.yahoo.co.jp/yconnect/v2/authorization",
+		tokenServer.URL,
+		profileServer.URL,
+	)
+	p.HTTPClient = tokenServer.Client()
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*yahoo.Session)
+
+	_, err = s.Authorize(p, url.Values{"code": {"abc"}})
+	a.NoError(err)
+	a.Equal("customised-access-token", s.AccessToken)
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal("jdoe", user.NickName)
+	a.Equal("abc123", user.UserID)
+}
+
+func Test_BeginAuth_WithPKCE(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider().WithPKCE()
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+
+	s := session.(*yahoo.Session)
+	a.NotEmpty(s.CodeVerifier)
+	a.Contains(s.AuthURL, "code_challenge=")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://api.login.yahoo.com/oauth2/request_auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*yahoo.Session)
+	a.Equal(s.AuthURL, "https://api.login.yahoo.com/oauth2/request_auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func provider() *yahoo.Provider {
+	return yahoo.New(os.Getenv("YAHOO_KEY"), os.Getenv("YAHOO_SECRET"), "/foo")
+}
@@ -0,0 +1,182 @@
+// Package yandex implements the OAuth2 protocol for authenticating users through Yandex.
+// This package can be used as a reference implementation of an OAuth2 provider for Goth.
+package yandex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authURL    string = "https://oauth.yandex.com/authorize"
+	tokenURL   string = "https://oauth.yandex.com/token"
+	profileURL string = "https://login.yandex.ru/info?format=json"
+	avatarURL  string = "https://avatars.yandex.net/get-yapic/%s/islands-200"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Yandex.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	config      *oauth2.Config
+
+	// AuthHeaderScheme is the scheme used in the Authorization header sent to
+	// the profile endpoint. Yandex expects the non-standard "OAuth" scheme
+	// rather than "Bearer"; callers that need the standard scheme (e.g. when
+	// talking to a proxy in front of Yandex) can override it.
+	AuthHeaderScheme string
+}
+
+// New creates a new Yandex provider and sets up important connection details.
+// You should always call `yandex.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:        clientKey,
+		Secret:           secret,
+		CallbackURL:      callbackURL,
+		AuthHeaderScheme: "OAuth",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return "yandex"
+}
+
+// Debug is a no-op for the yandex package.
+func (p *Provider) Debug(debug bool) {}
+
+// Client returns the HTTP client to use for requests to Yandex, falling back
+// to goth's default client when none was configured.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BeginAuth asks Yandex for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// authHeader builds the Authorization header value for a given access token
+// using the provider's configured scheme (Yandex's "OAuth" scheme by default).
+func (p *Provider) authHeader(accessToken string) string {
+	scheme := p.AuthHeaderScheme
+	if scheme == "" {
+		scheme = "OAuth"
+	}
+	return scheme + " " + accessToken
+}
+
+// FetchUser will go to Yandex and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	s := session.(*Session)
+	user := goth.User{
+		AccessToken:  s.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: s.RefreshToken,
+		ExpiresAt:    s.ExpiresAt,
+	}
+
+	req, err := http.NewRequest("GET", profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", p.authHeader(s.AccessToken))
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+// UnmarshalSession wil unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  authURL,
+			TokenURL: tokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	}
+	return c
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID              string `json:"id"`
+		DisplayName     string `json:"display_name"`
+		RealName        string `json:"real_name"`
+		Login           string `json:"login"`
+		DefaultEmail    string `json:"default_email"`
+		DefaultAvatarID string `json:"default_avatar_id"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.NickName = u.Login
+	user.Email = u.DefaultEmail
+
+	user.Name = u.DisplayName
+	if user.Name == "" {
+		user.Name = u.RealName
+	}
+
+	if u.DefaultAvatarID != "" {
+		user.AvatarURL = fmt.Sprintf(avatarURL, u.DefaultAvatarID)
+	}
+
+	return nil
+}
+
+//RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+//RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(oauth2.NoContext, token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}